@@ -64,6 +64,9 @@ type ctxExpiryKey struct{}
 
 type ctxFullMethodKey struct{}
 
+// Key used for storing the socket peer address on requests that do not arrive through gRPC.
+type ctxClientIPDirectKey struct{}
+
 type ApiServer struct {
 	logger               *zap.Logger
 	db                   *sql.DB
@@ -226,6 +229,7 @@ func StartApiServer(logger *zap.Logger, startupLogger *zap.Logger, db *sql.DB, j
 		// Ensure some request headers have required values.
 		// Override any value set by the client if needed.
 		r.Header.Set("Grpc-Timeout", gatewayContextTimeoutMs)
+		joinForwardedForHeader(r)
 
 		// Add constant response headers.
 		w.Header().Add("Cache-Control", "no-store, no-cache, must-revalidate")
@@ -434,12 +438,7 @@ func parseBearerAuth(hmacSecretByte []byte, auth string) (userID uuid.UUID, user
 }
 
 func parseToken(hmacSecretByte []byte, tokenString string) (userID uuid.UUID, username string, vars map[string]string, exp int64, ok bool) {
-	token, err := jwt.ParseWithClaims(tokenString, &SessionTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if s, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || s.Hash != crypto.SHA256 {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return hmacSecretByte, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &SessionTokenClaims{}, hmacSecretKeyFunc(hmacSecretByte))
 	if err != nil {
 		return
 	}
@@ -454,6 +453,27 @@ func parseToken(hmacSecretByte []byte, tokenString string) (userID uuid.UUID, us
 	return userID, claims.Username, claims.Vars, claims.ExpiresAt, true
 }
 
+func hmacSecretKeyFunc(hmacSecretByte []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if s, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || s.Hash != crypto.SHA256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return hmacSecretByte, nil
+	}
+}
+
+func parseVarsToken(hmacSecretByte []byte, tokenString string) (vars map[string]string, ok bool) {
+	token, err := jwt.ParseWithClaims(tokenString, &SessionTokenClaims{}, hmacSecretKeyFunc(hmacSecretByte))
+	if err != nil {
+		return
+	}
+	claims, ok := token.Claims.(*SessionTokenClaims)
+	if !ok || !token.Valid {
+		return
+	}
+	return claims.Vars, true
+}
+
 func decompressHandler(logger *zap.Logger, h http.Handler) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Header.Get("Content-Encoding") {
@@ -473,29 +493,149 @@ func decompressHandler(logger *zap.Logger, h http.Handler) http.HandlerFunc {
 	})
 }
 
-func extractClientAddressFromContext(logger *zap.Logger, ctx context.Context) (string, string) {
-	var clientAddr string
+func extractClientAddressFromContext(logger *zap.Logger, trustedProxies []*net.IPNet, ctx context.Context) (string, string) {
+	var forwarded []string
 	md, _ := metadata.FromIncomingContext(ctx)
 	if ips := md.Get("x-forwarded-for"); len(ips) > 0 {
 		// Look for gRPC-Gateway / LB header.
-		clientAddr = strings.Split(ips[0], ",")[0]
-	} else if peerInfo, ok := peer.FromContext(ctx); ok {
-		// If missing, try to look up gRPC peer info.
-		clientAddr = peerInfo.Addr.String()
+		forwarded = splitForwardedFor(ips)
+	}
+	var peerAddr string
+	if peerInfo, ok := peer.FromContext(ctx); ok {
+		// Also look up gRPC peer info, the direct source of the request.
+		peerAddr = peerInfo.Addr.String()
 	}
 
-	return extractClientAddress(logger, clientAddr)
+	return extractClientAddress(logger, resolveClientAddress(trustedProxies, forwarded, peerAddr))
 }
 
-func extractClientAddressFromRequest(logger *zap.Logger, r *http.Request) (string, string) {
-	var clientAddr string
-	if ips := r.Header.Get("x-forwarded-for"); len(ips) > 0 {
-		clientAddr = strings.Split(ips, ",")[0]
-	} else {
-		clientAddr = r.RemoteAddr
+func extractClientAddressFromRequest(logger *zap.Logger, trustedProxies []*net.IPNet, r *http.Request) (string, string) {
+	var forwarded []string
+	if ips := r.Header["X-Forwarded-For"]; len(ips) > 0 {
+		// Proxies may append their own header line rather than joining an existing one, read them all.
+		forwarded = splitForwardedFor(ips)
+	}
+
+	return extractClientAddress(logger, resolveClientAddress(trustedProxies, forwarded, r.RemoteAddr))
+}
+
+// The gateway only reads the first X-Forwarded-For header line before appending the peer address, but proxies
+// may append their own line rather than joining an existing one. Join them so the full chain reaches gRPC metadata.
+func joinForwardedForHeader(r *http.Request) {
+	if ips := r.Header["X-Forwarded-For"]; len(ips) > 1 {
+		r.Header.Set("X-Forwarded-For", strings.Join(ips, ", "))
+	}
+}
+
+func splitForwardedFor(values []string) []string {
+	forwarded := make([]string, 0, len(values))
+	for _, addr := range strings.Split(strings.Join(values, ","), ",") {
+		// Skip empty hops, such as those left by a stray comma, they carry no address to resolve.
+		if addr = strings.TrimSpace(addr); addr != "" {
+			forwarded = append(forwarded, addr)
+		}
+	}
+	return forwarded
+}
+
+func resolveClientAddress(trustedProxies []*net.IPNet, forwarded []string, peerAddr string) string {
+	if len(trustedProxies) == 0 {
+		// No trusted proxies configured, use the first forwarded address if there is one.
+		if len(forwarded) > 0 && forwarded[0] != "" {
+			return forwarded[0]
+		}
+		return peerAddr
+	}
+
+	// Walk back from the direct peer through the forwarded addresses, each one was appended by the hop after it.
+	// Stop at the first address not vouched for by a trusted proxy, anything further along may have been spoofed.
+	clientAddr := peerAddr
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if !isTrustedProxy(trustedProxies, clientAddr) {
+			break
+		}
+		clientAddr = forwarded[i]
+	}
+	return clientAddr
+}
+
+func extractClientIPDirectFromContext(logger *zap.Logger, gatewayAddrs []net.IP, ctx context.Context) string {
+	if clientIPDirect, ok := ctx.Value(ctxClientIPDirectKey{}).(string); ok {
+		// HTTP RPC and socket requests record their socket peer when received.
+		return clientIPDirect
 	}
 
-	return extractClientAddress(logger, clientAddr)
+	peerInfo, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	directAddr := peerInfo.Addr.String()
+	if isGatewayPeer(gatewayAddrs, directAddr) {
+		// The gateway appends the address of its own socket peer as the last forwarded address.
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := splitForwardedFor(md.Get("x-forwarded-for")); len(forwarded) > 0 {
+			directAddr = forwarded[len(forwarded)-1]
+		}
+	}
+
+	clientIPDirect, _ := extractClientAddress(logger, directAddr)
+	return clientIPDirect
+}
+
+func isGatewayPeer(gatewayAddrs []net.IP, addr string) bool {
+	ip := parseAddressIP(addr)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	for _, gatewayAddr := range gatewayAddrs {
+		if gatewayAddr.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTrustedProxy(trustedProxies []*net.IPNet, addr string) bool {
+	ip := parseAddressIP(addr)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		// The server's own gRPC-Gateway forwards requests over loopback, unless a socket address is set.
+		return true
+	}
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAddressIP(addr string) net.IP {
+	host := strings.TrimSpace(addr)
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return net.ParseIP(host)
+}
+
+func parseTrustedProxy(trustedProxy string) (*net.IPNet, error) {
+	if strings.Contains(trustedProxy, "/") {
+		_, trustedProxyNet, err := net.ParseCIDR(trustedProxy)
+		return trustedProxyNet, err
+	}
+	ip := net.ParseIP(trustedProxy)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %v", trustedProxy)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
 }
 
 func extractClientAddress(logger *zap.Logger, clientAddr string) (string, string) {
@@ -524,8 +664,8 @@ func extractClientAddress(logger *zap.Logger, clientAddr string) (string, string
 	return clientIP, clientPort
 }
 
-func traceApiBefore(ctx context.Context, logger *zap.Logger, metrics *Metrics, fullMethodName string, fn func(clientIP, clientPort string) error) error {
-	clientIP, clientPort := extractClientAddressFromContext(logger, ctx)
+func traceApiBefore(ctx context.Context, logger *zap.Logger, config Config, metrics *Metrics, fullMethodName string, fn func(clientIP, clientPort string) error) error {
+	clientIP, clientPort := extractClientAddressFromContext(logger, config.GetSocket().TrustedProxyNets, ctx)
 	start := time.Now()
 
 	// Execute the before hook itself.
@@ -536,8 +676,8 @@ func traceApiBefore(ctx context.Context, logger *zap.Logger, metrics *Metrics, f
 	return err
 }
 
-func traceApiAfter(ctx context.Context, logger *zap.Logger, metrics *Metrics, fullMethodName string, fn func(clientIP, clientPort string) error) {
-	clientIP, clientPort := extractClientAddressFromContext(logger, ctx)
+func traceApiAfter(ctx context.Context, logger *zap.Logger, config Config, metrics *Metrics, fullMethodName string, fn func(clientIP, clientPort string) error) {
+	clientIP, clientPort := extractClientAddressFromContext(logger, config.GetSocket().TrustedProxyNets, ctx)
 	start := time.Now()
 
 	// Execute the after hook itself.
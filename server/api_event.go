@@ -42,7 +42,7 @@ func (s *ApiServer) Event(ctx context.Context, in *api.Event) (*empty.Empty, err
 		}
 
 		// Execute the before function lambda wrapped in a trace for stats measurement.
-		err := traceApiBefore(ctx, s.logger, s.metrics, ctx.Value(ctxFullMethodKey{}).(string), beforeFn)
+		err := traceApiBefore(ctx, s.logger, s.config, s.metrics, ctx.Value(ctxFullMethodKey{}).(string), beforeFn)
 		if err != nil {
 			return nil, err
 		}
@@ -53,7 +53,7 @@ func (s *ApiServer) Event(ctx context.Context, in *api.Event) (*empty.Empty, err
 
 	// Add event to processing queue if there are any event handlers registered.
 	if fn := s.runtime.Event(); fn != nil {
-		clientIP, clientPort := extractClientAddressFromContext(s.logger, ctx)
+		clientIP, clientPort := extractClientAddressFromContext(s.logger, s.config.GetSocket().TrustedProxyNets, ctx)
 		evtCtx := NewRuntimeGoContext(ctx, s.config.GetName(), s.config.GetRuntime().Environment, RuntimeExecutionModeEvent, nil, ctx.Value(ctxExpiryKey{}).(int64), ctx.Value(ctxUserIDKey{}).(uuid.UUID).String(), ctx.Value(ctxUsernameKey{}).(string), ctx.Value(ctxVarsKey{}).(map[string]string), "", clientIP, clientPort)
 		fn(evtCtx, in)
 	}
@@ -65,7 +65,7 @@ func (s *ApiServer) Event(ctx context.Context, in *api.Event) (*empty.Empty, err
 		}
 
 		// Execute the after function lambda wrapped in a trace for stats measurement.
-		traceApiAfter(ctx, s.logger, s.metrics, ctx.Value(ctxFullMethodKey{}).(string), afterFn)
+		traceApiAfter(ctx, s.logger, s.config, s.metrics, ctx.Value(ctxFullMethodKey{}).(string), afterFn)
 	}
 
 	return &empty.Empty{}, nil
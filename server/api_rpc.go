@@ -35,6 +35,7 @@ import (
 var (
 	authTokenInvalidBytes    = []byte(`{"error":"Auth token invalid","message":"Auth token invalid","code":16}`)
 	httpKeyInvalidBytes      = []byte(`{"error":"HTTP key invalid","message":"HTTP key invalid","code":16}`)
+	varsTokenInvalidBytes    = []byte(`{"error":"Vars token invalid","message":"Vars token invalid","code":16}`)
 	noAuthBytes              = []byte(`{"error":"Auth token or HTTP key required","message":"Auth token or HTTP key required","code":16}`)
 	rpcIDMustBeSetBytes      = []byte(`{"error":"RPC ID must be set","message":"RPC ID must be set","code":3}`)
 	rpcFunctionNotFoundBytes = []byte(`{"error":"RPC function not found","message":"RPC function not found","code":5}`)
@@ -73,6 +74,21 @@ func (s *ApiServer) RpcFuncHttp(w http.ResponseWriter, r *http.Request) {
 			}
 			return
 		}
+		if varsToken := r.Header.Get("X-Nakama-Vars"); varsToken != "" && s.config.GetRuntime().HTTPKeyVars {
+			// Server to server callers may supply session vars, signed with the same HTTP key.
+			var varsOk bool
+			vars, varsOk = parseVarsToken([]byte(s.config.GetRuntime().HTTPKey), varsToken)
+			if !varsOk {
+				// Vars token not valid or expired.
+				w.Header().Set("content-type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				_, err := w.Write(varsTokenInvalidBytes)
+				if err != nil {
+					s.logger.Debug("Error writing response to client", zap.Error(err))
+				}
+				return
+			}
+		}
 	} else {
 		// No authentication present.
 		w.Header().Set("content-type", "application/json")
@@ -165,10 +181,12 @@ func (s *ApiServer) RpcFuncHttp(w http.ResponseWriter, r *http.Request) {
 		uid = userID.String()
 	}
 
-	clientIP, clientPort := extractClientAddressFromRequest(s.logger, r)
+	clientIP, clientPort := extractClientAddressFromRequest(s.logger, s.config.GetSocket().TrustedProxyNets, r)
+	clientIPDirect, _ := extractClientAddress(s.logger, r.RemoteAddr)
+	ctx := context.WithValue(r.Context(), ctxClientIPDirectKey{}, clientIPDirect)
 
 	// Execute the function.
-	result, fnErr, code := fn(r.Context(), queryParams, uid, username, vars, expiry, "", clientIP, clientPort, payload)
+	result, fnErr, code := fn(ctx, queryParams, uid, username, vars, expiry, "", clientIP, clientPort, payload)
 	if fnErr != nil {
 		response, _ := json.Marshal(map[string]interface{}{"error": fnErr, "message": fnErr.Error(), "code": code})
 		w.Header().Set("content-type", "application/json")
@@ -263,7 +281,7 @@ func (s *ApiServer) RpcFunc(ctx context.Context, in *api.Rpc) (*api.Rpc, error)
 		expiry = e.(int64)
 	}
 
-	clientIP, clientPort := extractClientAddressFromContext(s.logger, ctx)
+	clientIP, clientPort := extractClientAddressFromContext(s.logger, s.config.GetSocket().TrustedProxyNets, ctx)
 
 	result, fnErr, code := fn(ctx, queryParams, uid, username, vars, expiry, "", clientIP, clientPort, in.Payload)
 	if fnErr != nil {
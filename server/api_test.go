@@ -20,8 +20,10 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gofrs/uuid"
 	"github.com/golang/protobuf/jsonpb"
+	grpcgw "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/rtapi"
 	"github.com/heroiclabs/nakama/v2/apigrpc"
@@ -30,9 +32,13 @@ import (
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -227,3 +233,149 @@ func UserIDFromSession(session *api.Session) (uuid.UUID, error) {
 
 	return uuid.FromString(data["uid"].(string))
 }
+
+func TestResolveClientAddress(t *testing.T) {
+	trustedProxy, err := parseTrustedProxy("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("error parsing trusted proxy: %v", err.Error())
+	}
+	trustedProxies := []*net.IPNet{trustedProxy}
+
+	cases := []struct {
+		name           string
+		trustedProxies []*net.IPNet
+		forwarded      []string
+		peerAddr       string
+		expected       string
+	}{
+		{"no trusted proxies uses first forwarded", nil, []string{"1.1.1.1", "10.0.0.1"}, "10.0.0.2:7350", "1.1.1.1"},
+		{"no trusted proxies no forwarded", nil, nil, "2.2.2.2:7350", "2.2.2.2:7350"},
+		{"untrusted peer ignores forwarded", trustedProxies, []string{"1.1.1.1"}, "2.2.2.2:7350", "2.2.2.2:7350"},
+		{"trusted peer uses forwarded", trustedProxies, []string{"1.1.1.1"}, "10.0.0.2:7350", "1.1.1.1"},
+		{"spoofed entry before untrusted hop", trustedProxies, []string{"3.3.3.3", "1.1.1.1", "10.0.0.1"}, "10.0.0.2:7350", "1.1.1.1"},
+		{"gateway over loopback", trustedProxies, []string{"3.3.3.3", "1.1.1.1"}, "127.0.0.1:7349", "1.1.1.1"},
+		{"all hops trusted", trustedProxies, []string{"10.0.0.3", "10.0.0.1"}, "10.0.0.2:7350", "10.0.0.3"},
+		{"empty hops skipped", trustedProxies, []string{"1.1.1.1,, 10.0.0.1,"}, "10.0.0.2:7350", "1.1.1.1"},
+		{"no trusted proxies skips empty first hop", nil, []string{",1.1.1.1"}, "10.0.0.2:7350", "1.1.1.1"},
+	}
+
+	for _, c := range cases {
+		if actual := resolveClientAddress(c.trustedProxies, splitForwardedFor(c.forwarded), c.peerAddr); actual != c.expected {
+			t.Fatalf("%v: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestResolveClientAddressGatewaySocketAddress(t *testing.T) {
+	config := NewConfig(logger)
+	config.GetSocket().Address = "192.168.1.5"
+	config.GetSocket().TrustedProxies = []string{"10.0.0.0/8"}
+	CheckConfig(logger, config)
+
+	// The gateway dials the socket address, so the gRPC peer is not loopback.
+	if actual := resolveClientAddress(config.GetSocket().TrustedProxyNets, []string{"3.3.3.3", "1.1.1.1"}, "192.168.1.5:7349"); actual != "1.1.1.1" {
+		t.Fatalf("expected client IP 1.1.1.1, got %q", actual)
+	}
+}
+
+func TestExtractClientAddressFromRequestMultipleHeaders(t *testing.T) {
+	trustedProxy, err := parseTrustedProxy("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("error parsing trusted proxy: %v", err.Error())
+	}
+
+	r := httptest.NewRequest("POST", "/v2/rpc/test", nil)
+	r.RemoteAddr = "10.0.0.2:7350"
+	// Forged by the client, followed by a separate line added by the trusted proxy.
+	r.Header.Add("X-Forwarded-For", "3.3.3.3")
+	r.Header.Add("X-Forwarded-For", "1.1.1.1")
+
+	clientIP, _ := extractClientAddressFromRequest(logger, []*net.IPNet{trustedProxy}, r)
+	if clientIP != "1.1.1.1" {
+		t.Fatalf("expected client IP 1.1.1.1, got %q", clientIP)
+	}
+}
+
+func TestExtractClientAddressFromGatewayMultipleHeaders(t *testing.T) {
+	trustedProxy, err := parseTrustedProxy("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("error parsing trusted proxy: %v", err.Error())
+	}
+
+	r := httptest.NewRequest("POST", "/v2/account", nil)
+	r.RemoteAddr = "10.0.0.2:7350"
+	// Forged by the client, followed by a separate line added by the trusted proxy.
+	r.Header.Add("X-Forwarded-For", "3.3.3.3")
+	r.Header.Add("X-Forwarded-For", "1.1.1.1")
+	joinForwardedForHeader(r)
+
+	ctx, err := grpcgw.AnnotateIncomingContext(context.Background(), grpcgw.NewServeMux(), r, "/nakama.api.Nakama/GetAccount")
+	if err != nil {
+		t.Fatalf("error annotating context: %v", err.Error())
+	}
+	// The gateway reaches gRPC over loopback.
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7349}})
+
+	clientIP, _ := extractClientAddressFromContext(logger, []*net.IPNet{trustedProxy}, ctx)
+	if clientIP != "1.1.1.1" {
+		t.Fatalf("expected client IP 1.1.1.1, got %q", clientIP)
+	}
+}
+
+func TestExtractClientIPDirectFromContext(t *testing.T) {
+	r := httptest.NewRequest("POST", "/v2/account", nil)
+	r.RemoteAddr = "10.0.0.2:7350"
+	r.Header.Add("X-Forwarded-For", "1.1.1.1")
+	gatewayCtx, err := grpcgw.AnnotateIncomingContext(context.Background(), grpcgw.NewServeMux(), r, "/nakama.api.Nakama/GetAccount")
+	if err != nil {
+		t.Fatalf("error annotating context: %v", err.Error())
+	}
+	gatewayAddrs := []net.IP{net.ParseIP("192.168.1.5")}
+
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{"gateway over loopback", peer.NewContext(gatewayCtx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 7349}}), "10.0.0.2"},
+		{"gateway over socket address", peer.NewContext(gatewayCtx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.168.1.5"), Port: 7349}}), "10.0.0.2"},
+		{"direct gRPC client ignores forwarded", peer.NewContext(gatewayCtx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("2.2.2.2"), Port: 7349}}), "2.2.2.2"},
+		{"recorded socket peer", context.WithValue(context.Background(), ctxClientIPDirectKey{}, "3.3.3.3"), "3.3.3.3"},
+		{"no peer", context.Background(), ""},
+	}
+
+	for _, c := range cases {
+		if actual := extractClientIPDirectFromContext(logger, gatewayAddrs, c.ctx); actual != c.expected {
+			t.Fatalf("%v: expected %q, got %q", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestParseVarsToken(t *testing.T) {
+	sign := func(key string, exp int64) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &SessionTokenClaims{
+			Vars:      map[string]string{"store_region": "eu"},
+			ExpiresAt: exp,
+		})
+		signedToken, err := token.SignedString([]byte(key))
+		if err != nil {
+			t.Fatalf("error signing vars token: %v", err.Error())
+		}
+		return signedToken
+	}
+
+	vars, ok := parseVarsToken([]byte("httpkey"), sign("httpkey", time.Now().UTC().Unix()+60))
+	if !ok {
+		t.Fatal("expected valid vars token")
+	}
+	if vars["store_region"] != "eu" {
+		t.Fatalf("expected store_region var eu, got %q", vars["store_region"])
+	}
+
+	if _, ok := parseVarsToken([]byte("httpkey"), sign("otherkey", time.Now().UTC().Unix()+60)); ok {
+		t.Fatal("expected vars token signed with another key to be rejected")
+	}
+	if _, ok := parseVarsToken([]byte("httpkey"), sign("httpkey", time.Now().UTC().Unix()-60)); ok {
+		t.Fatal("expected expired vars token to be rejected")
+	}
+}
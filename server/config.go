@@ -16,6 +16,7 @@ package server
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -292,6 +293,33 @@ func CheckConfig(logger *zap.Logger, config Config) map[string]string {
 		config.GetSocket().TLSCert = []tls.Certificate{cert}
 	}
 
+	trustedProxyNets := make([]*net.IPNet, 0, len(config.GetSocket().TrustedProxies))
+	for _, trustedProxy := range config.GetSocket().TrustedProxies {
+		trustedProxyNet, err := parseTrustedProxy(trustedProxy)
+		if err != nil {
+			logger.Fatal("Trusted proxy must be a valid IP address or CIDR range", zap.String("param", "socket.trusted_proxies"), zap.String("value", trustedProxy))
+		}
+		trustedProxyNets = append(trustedProxyNets, trustedProxyNet)
+	}
+	var gatewayAddrs []net.IP
+	if config.GetSocket().Address != "" {
+		// The gateway reaches the gRPC API on the socket address rather than loopback.
+		var err error
+		gatewayAddrs, err = net.LookupIP(config.GetSocket().Address)
+		if err != nil {
+			logger.Fatal("Could not resolve socket address", zap.String("param", "socket.address"), zap.Error(err))
+		}
+		if len(trustedProxyNets) > 0 {
+			// The gateway must be trusted to forward client addresses.
+			for _, gatewayAddr := range gatewayAddrs {
+				trustedProxyNet, _ := parseTrustedProxy(gatewayAddr.String())
+				trustedProxyNets = append(trustedProxyNets, trustedProxyNet)
+			}
+		}
+	}
+	config.GetSocket().GatewayAddrs = gatewayAddrs
+	config.GetSocket().TrustedProxyNets = trustedProxyNets
+
 	// Set backwards-compatible defaults if overrides are not used.
 	if config.GetSocket().MaxRequestSizeBytes <= 0 {
 		config.GetSocket().MaxRequestSizeBytes = config.GetSocket().MaxMessageSizeBytes
@@ -402,6 +430,12 @@ func (c *config) Clone() (Config, error) {
 		}
 		nc.Socket.TLSCert = []tls.Certificate{cert}
 	}
+	nc.Socket.TrustedProxies = make([]string, len(c.Socket.TrustedProxies))
+	copy(nc.Socket.TrustedProxies, c.Socket.TrustedProxies)
+	nc.Socket.TrustedProxyNets = make([]*net.IPNet, len(c.Socket.TrustedProxyNets))
+	copy(nc.Socket.TrustedProxyNets, c.Socket.TrustedProxyNets)
+	nc.Socket.GatewayAddrs = make([]net.IP, len(c.Socket.GatewayAddrs))
+	copy(nc.Socket.GatewayAddrs, c.Socket.GatewayAddrs)
 	nc.Database.Addresses = make([]string, len(c.Database.Addresses))
 	copy(nc.Database.Addresses, c.Database.Addresses)
 	nc.Runtime.Env = make([]string, len(c.Runtime.Env))
@@ -556,6 +590,9 @@ type SocketConfig struct {
 	CertPEMBlock         []byte            `yaml:"-" json:"-"` // Created by fully reading the file contents of SSLCertificate, not set from input args directly.
 	KeyPEMBlock          []byte            `yaml:"-" json:"-"` // Created by fully reading the file contents of SSLPrivateKey, not set from input args directly.
 	TLSCert              []tls.Certificate `yaml:"-" json:"-"` // Created by processing CertPEMBlock and KeyPEMBlock, not set from input args directly.
+	TrustedProxies       []string          `yaml:"trusted_proxies" json:"trusted_proxies" usage:"List of IP addresses or CIDR ranges of proxies and load balancers allowed to set X-Forwarded-For client addresses. Loopback addresses and the socket address the gateway dials are always trusted. Default empty, which keeps the first forwarded address as given."`
+	TrustedProxyNets     []*net.IPNet      `yaml:"-" json:"-"` // Created by parsing TrustedProxies, not set from input args directly.
+	GatewayAddrs         []net.IP          `yaml:"-" json:"-"` // Created by resolving Address, the gateway reaches the gRPC API from these, not set from input args directly.
 }
 
 // NewTransportConfig creates a new TransportConfig struct.
@@ -645,6 +682,7 @@ type RuntimeConfig struct {
 	Env               []string          `yaml:"env" json:"env" usage:"Values to pass into Runtime as environment variables."`
 	Path              string            `yaml:"path" json:"path" usage:"Path for the server to scan for Lua and Go library files."`
 	HTTPKey           string            `yaml:"http_key" json:"http_key" usage:"Runtime HTTP Invocation key."`
+	HTTPKeyVars       bool              `yaml:"http_key_vars" json:"http_key_vars" usage:"Allow RPC calls authenticated with the HTTP key to supply session vars as a token signed with the HTTP key, sent in the 'X-Nakama-Vars' header. Default false."`
 	MinCount          int               `yaml:"min_count" json:"min_count" usage:"Minimum number of runtime instances to allocate. Default 16."`
 	MaxCount          int               `yaml:"max_count" json:"max_count" usage:"Maximum number of runtime instances to allocate. Default 48."`
 	CallStackSize     int               `yaml:"call_stack_size" json:"call_stack_size" usage:"Size of each runtime instance's call stack. Default 128."`
@@ -661,6 +699,7 @@ func NewRuntimeConfig() *RuntimeConfig {
 		Env:               make([]string, 0),
 		Path:              "",
 		HTTPKey:           "defaulthttpkey",
+		HTTPKeyVars:       false,
 		MinCount:          16,
 		MaxCount:          48,
 		CallStackSize:     128,
@@ -223,6 +223,11 @@ func (m *Metrics) GaugeRuntimes(value float64) {
 	m.prometheusScope.Gauge("lua_runtimes").Update(value)
 }
 
+// Increment the number of Lua runtime VMs discarded because the pool was full.
+func (m *Metrics) CountRuntimesDiscarded(delta int64) {
+	m.prometheusScope.Counter("lua_runtimes_discarded").Inc(delta)
+}
+
 // Set the absolute value of currently running authoritative matches.
 func (m *Metrics) GaugeAuthoritativeMatches(value float64) {
 	m.prometheusScope.Gauge("authoritative_matches").Update(value)
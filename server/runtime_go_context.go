@@ -32,15 +32,17 @@ func NewRuntimeGoContext(ctx context.Context, node string, env map[string]string
 	if userID != "" {
 		ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_USER_ID, userID)
 		ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_USERNAME, username)
-		if vars != nil {
-			ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_VARS, vars)
-		}
 		ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_USER_SESSION_EXP, sessionExpiry)
 		if sessionID != "" {
 			ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_SESSION_ID, sessionID)
 		}
 	}
 
+	if vars != nil {
+		// Vars may also be present without a user, for example on server to server calls.
+		ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_VARS, vars)
+	}
+
 	if clientIP != "" {
 		ctx = context.WithValue(ctx, runtime.RUNTIME_CTX_CLIENT_IP, clientIP)
 	}
@@ -1159,8 +1159,9 @@ func (rp *RuntimeProviderLua) Rpc(ctx context.Context, id string, queryParams ma
 		return "", ErrRuntimeRPCNotFound, codes.NotFound
 	}
 
+	clientIPDirect := extractClientIPDirectFromContext(rp.logger, rp.config.GetSocket().GatewayAddrs, ctx)
 	r.vm.SetContext(ctx)
-	result, fnErr, code := r.InvokeFunction(RuntimeExecutionModeRPC, lf, queryParams, userID, username, vars, expiry, sessionID, clientIP, clientPort, payload)
+	result, fnErr, code := r.InvokeFunction(RuntimeExecutionModeRPC, lf, queryParams, userID, username, vars, expiry, sessionID, clientIP, clientIPDirect, clientPort, payload)
 	r.vm.SetContext(context.Background())
 	rp.Put(r)
 
@@ -1224,8 +1225,9 @@ func (rp *RuntimeProviderLua) BeforeRt(ctx context.Context, id string, logger *z
 		return nil, errors.New("Could not run runtime Before function.")
 	}
 
+	clientIPDirect := extractClientIPDirectFromContext(rp.logger, rp.config.GetSocket().GatewayAddrs, ctx)
 	r.vm.SetContext(ctx)
-	result, fnErr, _ := r.InvokeFunction(RuntimeExecutionModeBefore, lf, nil, userID, username, vars, expiry, sessionID, clientIP, clientPort, envelopeMap)
+	result, fnErr, _ := r.InvokeFunction(RuntimeExecutionModeBefore, lf, nil, userID, username, vars, expiry, sessionID, clientIP, clientIPDirect, clientPort, envelopeMap)
 	r.vm.SetContext(context.Background())
 	rp.Put(r)
 
@@ -1289,8 +1291,9 @@ func (rp *RuntimeProviderLua) AfterRt(ctx context.Context, id string, logger *za
 		return errors.New("Could not run runtime After function.")
 	}
 
+	clientIPDirect := extractClientIPDirectFromContext(rp.logger, rp.config.GetSocket().GatewayAddrs, ctx)
 	r.vm.SetContext(ctx)
-	_, fnErr, _ := r.InvokeFunction(RuntimeExecutionModeAfter, lf, nil, userID, username, vars, expiry, sessionID, clientIP, clientPort, envelopeMap)
+	_, fnErr, _ := r.InvokeFunction(RuntimeExecutionModeAfter, lf, nil, userID, username, vars, expiry, sessionID, clientIP, clientIPDirect, clientPort, envelopeMap)
 	r.vm.SetContext(context.Background())
 	rp.Put(r)
 
@@ -1350,8 +1353,9 @@ func (rp *RuntimeProviderLua) BeforeReq(ctx context.Context, id string, logger *
 		}
 	}
 
+	clientIPDirect := extractClientIPDirectFromContext(rp.logger, rp.config.GetSocket().GatewayAddrs, ctx)
 	r.vm.SetContext(ctx)
-	result, fnErr, code := r.InvokeFunction(RuntimeExecutionModeBefore, lf, nil, userID, username, vars, expiry, "", clientIP, clientPort, reqMap)
+	result, fnErr, code := r.InvokeFunction(RuntimeExecutionModeBefore, lf, nil, userID, username, vars, expiry, "", clientIP, clientIPDirect, clientPort, reqMap)
 	r.vm.SetContext(context.Background())
 	rp.Put(r)
 
@@ -1449,8 +1453,9 @@ func (rp *RuntimeProviderLua) AfterReq(ctx context.Context, id string, logger *z
 		}
 	}
 
+	clientIPDirect := extractClientIPDirectFromContext(rp.logger, rp.config.GetSocket().GatewayAddrs, ctx)
 	r.vm.SetContext(ctx)
-	_, fnErr, _ := r.InvokeFunction(RuntimeExecutionModeAfter, lf, nil, userID, username, vars, expiry, "", clientIP, clientPort, resMap, reqMap)
+	_, fnErr, _ := r.InvokeFunction(RuntimeExecutionModeAfter, lf, nil, userID, username, vars, expiry, "", clientIP, clientIPDirect, clientPort, resMap, reqMap)
 	r.vm.SetContext(context.Background())
 	rp.Put(r)
 
@@ -1486,7 +1491,7 @@ func (rp *RuntimeProviderLua) MatchmakerMatched(ctx context.Context, entries []*
 		return "", false, errors.New("Runtime Matchmaker Matched function not found.")
 	}
 
-	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeMatchmaker, nil, 0, "", "", nil, "", "", "")
+	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeMatchmaker, nil, 0, "", "", nil, "", "", "", "")
 
 	entriesTable := r.vm.CreateTable(len(entries), 0)
 	for i, entry := range entries {
@@ -1553,7 +1558,7 @@ func (rp *RuntimeProviderLua) TournamentEnd(ctx context.Context, tournament *api
 		return errors.New("Runtime Tournament End function not found.")
 	}
 
-	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeTournamentEnd, nil, 0, "", "", nil, "", "", "")
+	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeTournamentEnd, nil, 0, "", "", nil, "", "", "", "")
 
 	tournamentTable := r.vm.CreateTable(0, 17)
 
@@ -1615,7 +1620,7 @@ func (rp *RuntimeProviderLua) TournamentReset(ctx context.Context, tournament *a
 		return errors.New("Runtime Tournament Reset function not found.")
 	}
 
-	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeTournamentReset, nil, 0, "", "", nil, "", "", "")
+	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeTournamentReset, nil, 0, "", "", nil, "", "", "", "")
 
 	tournamentTable := r.vm.CreateTable(0, 16)
 
@@ -1676,7 +1681,7 @@ func (rp *RuntimeProviderLua) LeaderboardReset(ctx context.Context, leaderboard
 		return errors.New("Runtime Leaderboard Reset function not found.")
 	}
 
-	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeLeaderboardReset, nil, 0, "", "", nil, "", "", "")
+	luaCtx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, RuntimeExecutionModeLeaderboardReset, nil, 0, "", "", nil, "", "", "", "")
 
 	leaderboardTable := r.vm.CreateTable(0, 13)
 
@@ -1745,6 +1750,11 @@ func (rp *RuntimeProviderLua) Put(r *RuntimeLua) {
 		// The pool is over capacity. Should never happen but guard anyway.
 		// Safe to continue processing, the runtime is just discarded.
 		rp.logger.Warn("Runtime pool full, discarding Lua runtime")
+		r.Stop()
+		// Keep the allocation count accurate so Get can still allocate replacements up to the max count.
+		currentCount := rp.currentCount.Dec()
+		rp.metrics.GaugeRuntimes(float64(currentCount))
+		rp.metrics.CountRuntimesDiscarded(1)
 	}
 }
 
@@ -1848,8 +1858,8 @@ func (r *RuntimeLua) GetCallback(e RuntimeExecutionMode, key string) *lua.LFunct
 	return nil
 }
 
-func (r *RuntimeLua) InvokeFunction(execMode RuntimeExecutionMode, fn *lua.LFunction, queryParams map[string][]string, uid string, username string, vars map[string]string, sessionExpiry int64, sid string, clientIP string, clientPort string, payloads ...interface{}) (interface{}, error, codes.Code) {
-	ctx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, execMode, queryParams, sessionExpiry, uid, username, vars, sid, clientIP, clientPort)
+func (r *RuntimeLua) InvokeFunction(execMode RuntimeExecutionMode, fn *lua.LFunction, queryParams map[string][]string, uid string, username string, vars map[string]string, sessionExpiry int64, sid string, clientIP string, clientIPDirect string, clientPort string, payloads ...interface{}) (interface{}, error, codes.Code) {
+	ctx := NewRuntimeLuaContext(r.vm, r.node, r.luaEnv, execMode, queryParams, sessionExpiry, uid, username, vars, sid, clientIP, clientIPDirect, clientPort)
 	lv := make([]lua.LValue, 0, len(payloads))
 	for _, payload := range payloads {
 		lv = append(lv, RuntimeLuaConvertValue(r.vm, payload))
@@ -32,6 +32,7 @@ const (
 	__RUNTIME_LUA_CTX_USER_SESSION_EXP = "user_session_exp"
 	__RUNTIME_LUA_CTX_SESSION_ID       = "session_id"
 	__RUNTIME_LUA_CTX_CLIENT_IP        = "client_ip"
+	__RUNTIME_LUA_CTX_CLIENT_IP_DIRECT = "client_ip_direct"
 	__RUNTIME_LUA_CTX_CLIENT_PORT      = "client_port"
 	__RUNTIME_LUA_CTX_MATCH_ID         = "match_id"
 	__RUNTIME_LUA_CTX_MATCH_NODE       = "match_node"
@@ -39,7 +40,7 @@ const (
 	__RUNTIME_LUA_CTX_MATCH_TICK_RATE  = "match_tick_rate"
 )
 
-func NewRuntimeLuaContext(l *lua.LState, node string, env *lua.LTable, mode RuntimeExecutionMode, queryParams map[string][]string, sessionExpiry int64, userID, username string, vars map[string]string, sessionID, clientIP, clientPort string) *lua.LTable {
+func NewRuntimeLuaContext(l *lua.LState, node string, env *lua.LTable, mode RuntimeExecutionMode, queryParams map[string][]string, sessionExpiry int64, userID, username string, vars map[string]string, sessionID, clientIP, clientIPDirect, clientPort string) *lua.LTable {
 	size := 3
 	if userID != "" {
 		size += 3
@@ -48,9 +49,15 @@ func NewRuntimeLuaContext(l *lua.LState, node string, env *lua.LTable, mode Runt
 		}
 	}
 
+	if vars != nil {
+		size++
+	}
 	if clientIP != "" {
 		size++
 	}
+	if clientIPDirect != "" {
+		size++
+	}
 	if clientPort != "" {
 		size++
 	}
@@ -68,22 +75,28 @@ func NewRuntimeLuaContext(l *lua.LState, node string, env *lua.LTable, mode Runt
 	if userID != "" {
 		lt.RawSetString(__RUNTIME_LUA_CTX_USER_ID, lua.LString(userID))
 		lt.RawSetString(__RUNTIME_LUA_CTX_USERNAME, lua.LString(username))
-		if vars != nil {
-			vt := l.CreateTable(0, len(vars))
-			for k, v := range vars {
-				vt.RawSetString(k, lua.LString(v))
-			}
-			lt.RawSetString(__RUNTIME_LUA_CTX_VARS, vt)
-		}
 		lt.RawSetString(__RUNTIME_LUA_CTX_USER_SESSION_EXP, lua.LNumber(sessionExpiry))
 		if sessionID != "" {
 			lt.RawSetString(__RUNTIME_LUA_CTX_SESSION_ID, lua.LString(sessionID))
 		}
 	}
 
+	if vars != nil {
+		// Vars may also be present without a user, for example on server to server calls.
+		vt := l.CreateTable(0, len(vars))
+		for k, v := range vars {
+			vt.RawSetString(k, lua.LString(v))
+		}
+		lt.RawSetString(__RUNTIME_LUA_CTX_VARS, vt)
+	}
+
 	if clientIP != "" {
 		lt.RawSetString(__RUNTIME_LUA_CTX_CLIENT_IP, lua.LString(clientIP))
 	}
+	if clientIPDirect != "" {
+		// The socket peer, before any forwarded addresses are considered.
+		lt.RawSetString(__RUNTIME_LUA_CTX_CLIENT_IP_DIRECT, lua.LString(clientIPDirect))
+	}
 	if clientPort != "" {
 		lt.RawSetString(__RUNTIME_LUA_CTX_CLIENT_PORT, lua.LString(clientPort))
 	}
@@ -23,13 +23,17 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"fmt"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gofrs/uuid"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/heroiclabs/nakama-common/api"
 	"github.com/heroiclabs/nakama-common/rtapi"
+	"github.com/heroiclabs/nakama/v2/internal/gopher-lua"
+	"go.uber.org/atomic"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -377,6 +381,76 @@ nakama.register_rpc(test.printWorld, "helloworld")`,
 	}
 }
 
+func TestRuntimeRegisterRPCWithHTTPKeyVars(t *testing.T) {
+	modules := map[string]string{
+		"http-invoke-vars": `
+local nakama = require("nakama")
+nakama.register_rpc(function(ctx, payload)
+	if ctx.vars then
+		return ctx.vars.store_region or ""
+	end
+	return ""
+end, "vars")`,
+	}
+
+	runtime, err := runtimeWithModules(t, modules)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	db := NewDB(t)
+	pipeline := NewPipeline(logger, cfg, db, jsonpbMarshaler, jsonpbUnmarshaler, nil, nil, nil, nil, nil, runtime)
+	apiServer := StartApiServer(logger, logger, db, jsonpbMarshaler, jsonpbUnmarshaler, cfg, nil, nil, nil, nil, nil, nil, nil, nil, metrics, pipeline, runtime)
+	defer apiServer.Stop()
+	defer func() {
+		cfg.GetRuntime().HTTPKeyVars = false
+	}()
+
+	signVars := func(key string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &SessionTokenClaims{
+			Vars:      map[string]string{"store_region": "eu"},
+			ExpiresAt: time.Now().UTC().Unix() + 60,
+		})
+		signedToken, err := token.SignedString([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signedToken
+	}
+
+	invoke := func(varsToken string) (int, string) {
+		client := &http.Client{}
+		request, _ := http.NewRequest("POST", "http://localhost:7350/v2/rpc/vars?http_key=defaulthttpkey", strings.NewReader("\"\""))
+		request.Header.Add("Content-Type", "Application/JSON")
+		request.Header.Add("X-Nakama-Vars", varsToken)
+		res, err := client.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		b, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, string(b)
+	}
+
+	// Vars tokens are ignored unless enabled.
+	cfg.GetRuntime().HTTPKeyVars = false
+	if code, body := invoke(signVars("otherkey")); code != http.StatusOK || body != `{"payload":""}` {
+		t.Fatalf("Invocation failed. Return result not expected: %v %v", code, body)
+	}
+
+	cfg.GetRuntime().HTTPKeyVars = true
+	if code, body := invoke(signVars("otherkey")); code != http.StatusUnauthorized || body != string(varsTokenInvalidBytes) {
+		t.Fatalf("Invocation failed. Return result not expected: %v %v", code, body)
+	}
+	if code, body := invoke(signVars(cfg.GetRuntime().HTTPKey)); code != http.StatusOK || body != `{"payload":"eu"}` {
+		t.Fatalf("Invocation failed. Return result not expected: %v %v", code, body)
+	}
+}
+
 func TestRuntimeHTTPRequest(t *testing.T) {
 	modules := map[string]string{
 		"test": `
@@ -999,3 +1073,51 @@ nk.group_delete(group.id)`,
 		t.Fatal(err.Error())
 	}
 }
+
+func TestRuntimeLuaPoolDiscardAccounting(t *testing.T) {
+	rp := &RuntimeProviderLua{
+		logger:  logger,
+		metrics: metrics,
+		// Deliberately smaller than max count so returning runtimes overfills the pool.
+		poolCh:       make(chan *RuntimeLua, 1),
+		maxCount:     3,
+		currentCount: atomic.NewUint32(0),
+		newFn: func() *RuntimeLua {
+			return &RuntimeLua{vm: lua.NewState()}
+		},
+	}
+
+	runtimes := make([]*RuntimeLua, 0, 3)
+	for i := 0; i < 3; i++ {
+		r, err := rp.Get(context.Background())
+		if err != nil {
+			t.Fatalf("error getting runtime %v: %v", i, err.Error())
+		}
+		runtimes = append(runtimes, r)
+	}
+
+	// One runtime is pooled, the remaining two are discarded.
+	for _, r := range runtimes {
+		rp.Put(r)
+	}
+	if count := rp.currentCount.Load(); count != 1 {
+		t.Fatalf("expected current count 1 after discards, got %v", count)
+	}
+
+	// The pooled runtime plus two fresh allocations must be available.
+	for i := 0; i < 3; i++ {
+		if _, err := rp.Get(context.Background()); err != nil {
+			t.Fatalf("error getting runtime %v after discards: %v", i, err.Error())
+		}
+	}
+	if count := rp.currentCount.Load(); count != 3 {
+		t.Fatalf("expected current count 3, got %v", count)
+	}
+
+	// No further allocations are allowed beyond max count.
+	ctx, ctxCancelFn := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer ctxCancelFn()
+	if _, err := rp.Get(ctx); err == nil {
+		t.Fatal("expected error getting runtime beyond max count")
+	}
+}
@@ -67,7 +67,8 @@ func NewSocketWsAcceptor(logger *zap.Logger, config Config, sessionRegistry Sess
 			return
 		}
 
-		clientIP, clientPort := extractClientAddressFromRequest(logger, r)
+		clientIP, clientPort := extractClientAddressFromRequest(logger, config.GetSocket().TrustedProxyNets, r)
+		clientIPDirect, _ := extractClientAddress(logger, r.RemoteAddr)
 
 		status := false
 		if r.URL.Query().Get("status") == "true" {
@@ -88,7 +89,7 @@ func NewSocketWsAcceptor(logger *zap.Logger, config Config, sessionRegistry Sess
 		metrics.CountWebsocketOpened(1)
 
 		// Wrap the connection for application handling.
-		session := NewSessionWS(logger, config, format, sessionID, userID, username, vars, expiry, clientIP, clientPort, jsonpbMarshaler, jsonpbUnmarshaler, conn, sessionRegistry, matchmaker, tracker, pipeline, runtime)
+		session := NewSessionWS(logger, config, format, sessionID, userID, username, vars, expiry, clientIP, clientIPDirect, clientPort, jsonpbMarshaler, jsonpbUnmarshaler, conn, sessionRegistry, matchmaker, tracker, pipeline, runtime)
 
 		// Add to the session registry.
 		sessionRegistry.Add(session)